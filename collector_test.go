@@ -0,0 +1,122 @@
+package main
+
+import "testing"
+
+func TestCompileAnyPatternEmptyIsNoConstraint(t *testing.T) {
+	re, err := compileAnyPattern(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if re != nil {
+		t.Errorf("compileAnyPattern(nil) = %v, want nil", re)
+	}
+}
+
+func TestCompileAnyPatternInvalidRegexp(t *testing.T) {
+	if _, err := compileAnyPattern([]string{"("}); err == nil {
+		t.Fatal("expected an error for an invalid pattern, got nil")
+	}
+}
+
+func TestCompileAnyPatternOrsMultiplePatterns(t *testing.T) {
+	re, err := compileAnyPattern([]string{"^eth", "^wlan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"eth0", "wlan0"} {
+		if !re.MatchString(name) {
+			t.Errorf("expected %q to match", name)
+		}
+	}
+	if re.MatchString("lo") {
+		t.Error(`expected "lo" not to match`)
+	}
+}
+
+func TestMatchesFilter(t *testing.T) {
+	include, err := compileAnyPattern([]string{"^eth"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exclude, err := compileAnyPattern([]string{"^eth1$"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"eth0", true},
+		{"eth1", false}, // excluded wins even though it also matches include
+		{"wlan0", false},
+		{"lo", false},
+	}
+
+	for _, tc := range tests {
+		if got := matchesFilter(tc.name, include, exclude); got != tc.want {
+			t.Errorf("matchesFilter(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMatchesFilterNilPatternsImposeNoConstraint(t *testing.T) {
+	if !matchesFilter("anything", nil, nil) {
+		t.Error("matchesFilter with nil include/exclude should always match")
+	}
+}
+
+func TestWithoutDetailStripsNestedDetailFieldsOnly(t *testing.T) {
+	in := Snapshot{
+		"cpu": Snapshot{
+			"percent":    42.0,
+			"processors": []Processor{{VendorID: "GenuineIntel"}},
+		},
+		"disk": Snapshot{
+			"total":      uint64(100),
+			"partitions": []Disk{{Device: "/dev/sda1"}},
+		},
+		"time": "2026-01-01 00:00:00.000Z",
+	}
+
+	out := withoutDetail(in)
+
+	cpuOut, ok := out["cpu"].(Snapshot)
+	if !ok {
+		t.Fatalf("expected cpu entry to remain a Snapshot, got %T", out["cpu"])
+	}
+	if _, present := cpuOut["processors"]; present {
+		t.Error("expected processors to be stripped from cpu")
+	}
+	if cpuOut["percent"] != 42.0 {
+		t.Errorf("expected percent to survive stripping, got %v", cpuOut["percent"])
+	}
+
+	diskOut, ok := out["disk"].(Snapshot)
+	if !ok {
+		t.Fatalf("expected disk entry to remain a Snapshot, got %T", out["disk"])
+	}
+	if _, present := diskOut["partitions"]; present {
+		t.Error("expected partitions to be stripped from disk")
+	}
+
+	if out["time"] != "2026-01-01 00:00:00.000Z" {
+		t.Errorf("expected non-map fields to pass through unchanged, got %v", out["time"])
+	}
+}
+
+func TestWithoutDetailDoesNotMutateInput(t *testing.T) {
+	in := Snapshot{
+		"cpu": Snapshot{
+			"percent":    1.0,
+			"processors": []Processor{{VendorID: "x"}},
+		},
+	}
+
+	withoutDetail(in)
+
+	cpuIn := in["cpu"].(Snapshot)
+	if _, present := cpuIn["processors"]; !present {
+		t.Error("withoutDetail must not mutate its input snapshot")
+	}
+}
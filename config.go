@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// CollectorConfig enables or disables a single collector and carries its
+// per-collector options, e.g. which NICs or mountpoints to include/exclude
+// via regexp.
+type CollectorConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Include []string `mapstructure:"include"`
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// Config is the top-level configuration file, loaded with viper so it can
+// be supplied as YAML or JSON.
+type Config struct {
+	ListenAddr string                     `mapstructure:"listen_addr"`
+	Interval   time.Duration              `mapstructure:"interval"`
+	Collectors map[string]CollectorConfig `mapstructure:"collectors"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		ListenAddr: ":7080",
+		Interval:   10 * time.Second,
+		Collectors: map[string]CollectorConfig{
+			"cpu":     {Enabled: true},
+			"memory":  {Enabled: true},
+			"network": {Enabled: true},
+			"disk":    {Enabled: true},
+			"load":    {Enabled: true},
+			"host":    {Enabled: true},
+		},
+	}
+}
+
+// loadConfig reads the named config file (YAML or JSON, detected by its
+// extension) and merges it over defaultConfig. A missing file isn't an
+// error - the service just runs with every built-in collector enabled.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return cfg, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	if err := v.Unmarshal(&cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	if cfg.Interval <= 0 {
+		return cfg, fmt.Errorf("parsing config %s: interval must be positive, got %s", path, cfg.Interval)
+	}
+
+	return cfg, nil
+}
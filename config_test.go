@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigMissingFileReturnsDefaults(t *testing.T) {
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := defaultConfig()
+	if cfg.ListenAddr != want.ListenAddr || cfg.Interval != want.Interval {
+		t.Errorf("loadConfig on a missing file = %+v, want %+v", cfg, want)
+	}
+	if !cfg.Collectors["cpu"].Enabled {
+		t.Error("expected cpu collector to default to enabled")
+	}
+}
+
+func TestLoadConfigOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stormguard.yaml")
+	body := `
+listen_addr: ":9090"
+interval: 30s
+collectors:
+  cpu:
+    enabled: true
+  network:
+    enabled: false
+  disk:
+    enabled: true
+    exclude:
+      - "^/proc"
+`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("writing config fixture: %v", err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.ListenAddr != ":9090" {
+		t.Errorf("ListenAddr = %q, want :9090", cfg.ListenAddr)
+	}
+	if cfg.Interval != 30*time.Second {
+		t.Errorf("Interval = %v, want 30s", cfg.Interval)
+	}
+	if cfg.Collectors["network"].Enabled {
+		t.Error("expected network collector to be disabled by the override file")
+	}
+	if len(cfg.Collectors["disk"].Exclude) != 1 || cfg.Collectors["disk"].Exclude[0] != "^/proc" {
+		t.Errorf("disk exclude = %v, want [^/proc]", cfg.Collectors["disk"].Exclude)
+	}
+}
+
+func TestLoadConfigRejectsNonPositiveInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stormguard.yaml")
+	if err := os.WriteFile(path, []byte("interval: 0s\n"), 0o644); err != nil {
+		t.Fatalf("writing config fixture: %v", err)
+	}
+
+	if _, err := loadConfig(path); err == nil {
+		t.Fatal("expected an error for a non-positive interval, got nil")
+	}
+}
@@ -1,107 +1,131 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
-	"sync"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/net"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	configPath := flag.String("config", "stormguard.yaml", "path to config file (YAML or JSON)")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Tracks the previous network sample so rates are true per-second deltas
+	rates := newRateTracker()
+
+	collectors, err := buildCollectors(cfg, rates)
+	if err != nil {
+		log.Fatalf("Failed to build collectors: %v", err)
+	}
+
 	r := chi.NewRouter()
 
-	// Shared metrics and mutex
-	var metrics SystemMetrics
-	var metricsMutex sync.Mutex
+	// Latest merged snapshot, swapped in atomically so the HTTP handler
+	// never holds a lock across the (potentially slow) write to the client
+	// socket.
+	var latest atomic.Pointer[Snapshot]
 
-	// Periodically update the metrics every 10 seconds
+	// Fans out each refreshed snapshot to /stream subscribers
+	hub := newMetricsHub()
+
+	// Prometheus collectors kept in sync with the background sampling loop
+	reg := prometheus.NewRegistry()
+	pm := newPromMetrics(reg)
+
+	// Periodically refresh the metrics until ctx is canceled
 	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
 		for {
-			newMetrics, err := getMetrics()
-			if err != nil {
-				log.Printf("Error getting metrics: %v", err)
-			} else {
-				metricsMutex.Lock()
-				metrics = newMetrics
-				metricsMutex.Unlock()
+			snapshot := make(Snapshot, len(collectors))
+			if prev := latest.Load(); prev != nil {
+				// Carry forward the last known-good reading for any
+				// collector that errors below, rather than dropping its
+				// key from the published snapshot for this tick.
+				for name, out := range *prev {
+					snapshot[name] = out
+				}
+			}
+			for _, c := range collectors {
+				out, err := c.Collect(ctx)
+				if err != nil {
+					log.Printf("Error collecting %s metrics: %v", c.Name(), err)
+					continue
+				}
+				snapshot[c.Name()] = out
+			}
+			snapshot["time"] = time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+
+			latest.Store(&snapshot)
+			hub.publish(snapshot)
+			pm.update(snapshot)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
 			}
-			time.Sleep(10 * time.Second)
 		}
 	}()
 
 	// Define the endpoint
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		metricsMutex.Lock()
-		defer metricsMutex.Unlock()
+		snapshot := latest.Load()
+		if snapshot == nil {
+			http.Error(w, "metrics not collected yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		body := *snapshot
+		if r.URL.Query().Get("detail") != "full" {
+			body = withoutDetail(body)
+		}
 
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(metrics); err != nil {
+		if err := json.NewEncoder(w).Encode(body); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	})
 
-	// Start the server
-	log.Println("Starting server on :7080")
-	if err := http.ListenAndServe(":7080", r); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
-	}
-}
+	// Stream metrics to clients as Server-Sent Events, one frame per refresh
+	r.Get("/stream", streamHandler(hub))
 
-type SystemMetrics struct {
-	Time          string  `json:"time"`
-	CPUPercent    float64 `json:"cpu_perc"`
-	MemoryPercent float64 `json:"memory_perc"`
-	RxRate        uint64  `json:"rx_rate"`
-	TxRate        uint64  `json:"tx_rate"`
-	RxBytes       uint64  `json:"rx_bytes"`
-	TxBytes       uint64  `json:"tx_bytes"`
-}
+	// Expose the collected metrics as a Prometheus scrape target
+	r.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
 
-func getMetrics() (SystemMetrics, error) {
-	// Get current time
-	currentTime := time.Now().UTC().Format("2006-01-02 15:04:05.000Z")
+	srv := &http.Server{Addr: cfg.ListenAddr, Handler: r}
 
-	// Get CPU percentage
-	cpuPercents, err := cpu.Percent(0, false)
-	if err != nil {
-		return SystemMetrics{}, err
-	}
-	cpuPercent := cpuPercents[0]
-
-	// Get memory usage
-	vmem, err := mem.VirtualMemory()
-	if err != nil {
-		return SystemMetrics{}, err
-	}
-	memoryPercent := vmem.UsedPercent
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server: %v", err)
+		}
+	}()
 
-	// Get network IO counters
-	netIO, err := net.IOCounters(false)
-	if err != nil {
-		return SystemMetrics{}, err
-	}
-	rxBytes := netIO[0].BytesRecv
-	txBytes := netIO[0].BytesSent
-
-	// Calculate network rates
-	rxRate := netIO[0].PacketsRecv // packets received per interval
-	txRate := netIO[0].PacketsSent // packets sent per interval
-
-	metrics := SystemMetrics{
-		Time:          currentTime,
-		CPUPercent:    cpuPercent,
-		MemoryPercent: memoryPercent,
-		RxRate:        rxRate,
-		TxRate:        txRate,
-		RxBytes:       rxBytes,
-		TxBytes:       txBytes,
+	log.Printf("Starting server on %s", cfg.ListenAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Server failed to start: %v", err)
 	}
-
-	return metrics, nil
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// cpuSampleInterval is how long cpu.Percent blocks to measure a real delta.
+// A non-zero interval avoids the bogus 0%/100% gopsutil can return on the
+// very first call with interval 0.
+const cpuSampleInterval = 500 * time.Millisecond
+
+// Processor is the per-logical-CPU detail reported by the cpu collector.
+type Processor struct {
+	VendorID string  `json:"vendor_id"`
+	Family   string  `json:"family"`
+	Model    string  `json:"model"`
+	Mhz      float64 `json:"mhz"`
+	Percent  float64 `json:"percent"`
+}
+
+// Disk is the per-partition detail reported by the disk collector.
+type Disk struct {
+	Device      string  `json:"device"`
+	MountPoint  string  `json:"mount_point"`
+	FSType      string  `json:"fs_type"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// Interface is the per-NIC detail reported by the network collector.
+type Interface struct {
+	Name      string `json:"name"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	TxPackets uint64 `json:"tx_packets"`
+}
+
+// netSample is the previous network reading a rateTracker needs in order to
+// turn gopsutil's cumulative counters into per-second rates.
+type netSample struct {
+	time      time.Time
+	rxBytes   uint64
+	txBytes   uint64
+	rxPackets uint64
+	txPackets uint64
+}
+
+// rateTracker keeps the previous network sample around, guarded by mu, so
+// successive calls can report true bytes/s and packets/s deltas instead of
+// raw cumulative counters. It's shared by the network collector across
+// scrapes.
+type rateTracker struct {
+	mu   sync.Mutex
+	prev netSample
+	have bool
+}
+
+func newRateTracker() *rateTracker {
+	return &rateTracker{}
+}
+
+// rates turns the latest cumulative counters into per-second deltas against
+// the previous sample, then stores the latest sample for next time. The
+// first call has nothing to compare against, so it reports zero rates
+// rather than a misleading delta.
+func (rt *rateTracker) rates(now time.Time, rxBytes, txBytes, rxPackets, txPackets uint64) (rxRate, txRate, rxPacketRate, txPacketRate float64) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.have {
+		elapsed := now.Sub(rt.prev.time).Seconds()
+		if elapsed > 0 {
+			rxRate = deltaPerSecond(rt.prev.rxBytes, rxBytes, elapsed)
+			txRate = deltaPerSecond(rt.prev.txBytes, txBytes, elapsed)
+			rxPacketRate = deltaPerSecond(rt.prev.rxPackets, rxPackets, elapsed)
+			txPacketRate = deltaPerSecond(rt.prev.txPackets, txPackets, elapsed)
+		}
+	}
+
+	rt.prev = netSample{time: now, rxBytes: rxBytes, txBytes: txBytes, rxPackets: rxPackets, txPackets: txPackets}
+	rt.have = true
+	return rxRate, txRate, rxPacketRate, txPacketRate
+}
+
+// deltaPerSecond returns 0 instead of a huge underflowed value when a
+// counter has reset (e.g. an interface flap), since prev can't exceed now
+// in any other case.
+func deltaPerSecond(prev, now uint64, elapsedSeconds float64) float64 {
+	if now < prev {
+		return 0
+	}
+	return float64(now-prev) / elapsedSeconds
+}
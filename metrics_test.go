@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeltaPerSecond(t *testing.T) {
+	cases := []struct {
+		name    string
+		prev    uint64
+		now     uint64
+		elapsed float64
+		want    float64
+	}{
+		{"normal increase", 1000, 2000, 2, 500},
+		{"unchanged counter", 1000, 1000, 1, 0},
+		{"counter reset (e.g. interface flap)", 5000, 100, 1, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := deltaPerSecond(tc.prev, tc.now, tc.elapsed)
+			if got != tc.want {
+				t.Errorf("deltaPerSecond(%d, %d, %v) = %v, want %v", tc.prev, tc.now, tc.elapsed, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRateTrackerFirstCallReportsZero(t *testing.T) {
+	rt := newRateTracker()
+
+	rxRate, txRate, rxPacketRate, txPacketRate := rt.rates(time.Now(), 1000, 2000, 10, 20)
+	if rxRate != 0 || txRate != 0 || rxPacketRate != 0 || txPacketRate != 0 {
+		t.Errorf("first sample should report zero rates, got rx=%v tx=%v rxPkt=%v txPkt=%v", rxRate, txRate, rxPacketRate, txPacketRate)
+	}
+}
+
+func TestRateTrackerComputesDeltaAcrossCalls(t *testing.T) {
+	rt := newRateTracker()
+
+	start := time.Now()
+	rt.rates(start, 1000, 2000, 10, 20)
+
+	rxRate, txRate, rxPacketRate, txPacketRate := rt.rates(start.Add(2*time.Second), 3000, 2500, 30, 25)
+
+	if rxRate != 1000 {
+		t.Errorf("rxRate = %v, want 1000", rxRate)
+	}
+	if txRate != 250 {
+		t.Errorf("txRate = %v, want 250", txRate)
+	}
+	if rxPacketRate != 10 {
+		t.Errorf("rxPacketRate = %v, want 10", rxPacketRate)
+	}
+	if txPacketRate != 2.5 {
+		t.Errorf("txPacketRate = %v, want 2.5", txPacketRate)
+	}
+}
+
+func TestRateTrackerHandlesCounterReset(t *testing.T) {
+	rt := newRateTracker()
+
+	start := time.Now()
+	rt.rates(start, 5000, 5000, 50, 50)
+
+	rxRate, txRate, _, _ := rt.rates(start.Add(1*time.Second), 100, 100, 1, 1)
+
+	if rxRate != 0 || txRate != 0 {
+		t.Errorf("a counter reset should report zero rate, got rx=%v tx=%v", rxRate, txRate)
+	}
+}
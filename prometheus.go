@@ -0,0 +1,94 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// promMetrics holds the Prometheus collectors kept in sync with the
+// background sampling loop so the service can be scraped directly instead
+// of requiring a bespoke JSON client. Only the cpu, memory and network
+// collectors currently have a Prometheus mapping.
+type promMetrics struct {
+	cpuPercent    prometheus.Gauge
+	memoryPercent prometheus.Gauge
+	rxBytes       prometheus.Counter
+	txBytes       prometheus.Counter
+	rxRate        prometheus.Gauge
+	txRate        prometheus.Gauge
+
+	prevRxBytes uint64
+	prevTxBytes uint64
+}
+
+func newPromMetrics(reg prometheus.Registerer) *promMetrics {
+	pm := &promMetrics{
+		cpuPercent: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cpu_perc",
+			Help: "Current CPU utilization percentage.",
+		}),
+		memoryPercent: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "memory_perc",
+			Help: "Current memory utilization percentage.",
+		}),
+		rxBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "rx_bytes",
+			Help: "Cumulative bytes received across the network interfaces matched by the network collector's filters.",
+		}),
+		txBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tx_bytes",
+			Help: "Cumulative bytes sent across the network interfaces matched by the network collector's filters.",
+		}),
+		rxRate: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "rx_rate",
+			Help: "Bytes received per second, since the previous sample.",
+		}),
+		txRate: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tx_rate",
+			Help: "Bytes sent per second, since the previous sample.",
+		}),
+	}
+
+	reg.MustRegister(pm.cpuPercent, pm.memoryPercent, pm.rxBytes, pm.txBytes, pm.rxRate, pm.txRate)
+	return pm
+}
+
+// update syncs the Prometheus collectors to the latest snapshot. Collectors
+// that aren't enabled in Config are simply absent from the snapshot, so
+// their Prometheus series just stop advancing.
+func (pm *promMetrics) update(s Snapshot) {
+	if cpuOut, ok := s["cpu"].(Snapshot); ok {
+		if percent, ok := cpuOut["percent"].(float64); ok {
+			pm.cpuPercent.Set(percent)
+		}
+	}
+
+	if memOut, ok := s["memory"].(Snapshot); ok {
+		if percent, ok := memOut["percent"].(float64); ok {
+			pm.memoryPercent.Set(percent)
+		}
+	}
+
+	netOut, ok := s["network"].(Snapshot)
+	if !ok {
+		return
+	}
+
+	if rxRate, ok := netOut["rx_rate"].(float64); ok {
+		pm.rxRate.Set(rxRate)
+	}
+	if txRate, ok := netOut["tx_rate"].(float64); ok {
+		pm.txRate.Set(txRate)
+	}
+
+	// rx_bytes/tx_bytes are modeled as Counters, so add the delta from the
+	// previous cumulative total rather than overwriting it.
+	rxBytes, _ := netOut["rx_bytes"].(uint64)
+	txBytes, _ := netOut["tx_bytes"].(uint64)
+	if rxBytes > pm.prevRxBytes {
+		pm.rxBytes.Add(float64(rxBytes - pm.prevRxBytes))
+	}
+	if txBytes > pm.prevTxBytes {
+		pm.txBytes.Add(float64(txBytes - pm.prevTxBytes))
+	}
+	pm.prevRxBytes, pm.prevTxBytes = rxBytes, txBytes
+}
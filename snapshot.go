@@ -0,0 +1,31 @@
+package main
+
+// detailFields lists the per-item breakdowns collectors add to their
+// output (per-core, per-partition, per-NIC) that are only included in the
+// response when the caller asks for "?detail=full".
+var detailFields = []string{"processors", "partitions", "interfaces"}
+
+// withoutDetail returns a copy of s with each collector's detail fields
+// stripped, leaving only the scalar aggregates. It never mutates s, since s
+// is also the value stored in the atomic snapshot pointer and published to
+// /stream subscribers.
+func withoutDetail(s Snapshot) Snapshot {
+	out := make(Snapshot, len(s))
+	for name, v := range s {
+		sub, ok := v.(Snapshot)
+		if !ok {
+			out[name] = v
+			continue
+		}
+
+		trimmed := make(Snapshot, len(sub))
+		for k, val := range sub {
+			trimmed[k] = val
+		}
+		for _, field := range detailFields {
+			delete(trimmed, field)
+		}
+		out[name] = trimmed
+	}
+	return out
+}
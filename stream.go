@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// metricsHub fans out each refreshed Snapshot to every subscribed /stream
+// client, so the background collector loop only has to run once no matter
+// how many dashboards are watching.
+type metricsHub struct {
+	mu   sync.Mutex
+	subs map[chan Snapshot]struct{}
+}
+
+func newMetricsHub() *metricsHub {
+	return &metricsHub{subs: make(map[chan Snapshot]struct{})}
+}
+
+func (h *metricsHub) subscribe() chan Snapshot {
+	ch := make(chan Snapshot, 1)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *metricsHub) unsubscribe(ch chan Snapshot) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+	close(ch)
+}
+
+// publish hands the latest sample to every subscriber. A subscriber whose
+// buffer is still full (i.e. it hasn't drained the previous frame yet) is
+// skipped rather than blocking the whole broadcast.
+func (h *metricsHub) publish(s Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+// streamHandler serves Snapshots as Server-Sent Events, pushing a new frame
+// each time the background collector loop refreshes. Like Consul's
+// /v1/agent/metrics/stream, it detects whether the ResponseWriter supports
+// flushing up front and keeps the connection open until the client
+// disconnects.
+//
+// The hub always publishes the full snapshot (detail included), same as the
+// background loop keeps for "/"; each subscriber trims it down to scalar
+// aggregates unless it asked for "?detail=full", mirroring "/"'s behavior.
+func streamHandler(hub *metricsHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		detail := r.URL.Query().Get("detail") == "full"
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ch := hub.subscribe()
+		defer hub.unsubscribe(ch)
+
+		ctx := r.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s, ok := <-ch:
+				if !ok {
+					return
+				}
+				if !detail {
+					s = withoutDetail(s)
+				}
+				data, err := json.Marshal(s)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}